@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"github.com/spiral/roadrunner/v2/pkg/pool"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+)
+
+// Config is the `jobs` config section: the declared pipelines, which of
+// them to consume on Serve, and the shared worker pool/poller settings.
+type Config struct {
+	// NumPollers is the number of poller goroutines pulling from the
+	// shared priority queue.
+	NumPollers uint8 `mapstructure:"num_pollers"`
+
+	// PipelineSize is the capacity hint for the shared priority queue.
+	PipelineSize int64 `mapstructure:"pipeline_size"`
+
+	// Pool configures the worker pool jobs are dispatched to.
+	Pool *pool.Config `mapstructure:"pool"`
+
+	// Pipelines declares every known pipeline by name.
+	Pipelines map[string]*pipeline.Pipeline `mapstructure:"pipelines"`
+
+	// Consume lists the pipelines to start consuming from on Serve; a
+	// pipeline not listed here stays push-only, as Declare also starts
+	// consuming only pipelines whose names are listed here.
+	Consume []string `mapstructure:"consume"`
+
+	// Middleware orders the collected jobs.Middleware chain by name;
+	// middleware not listed here runs in registration order, after the
+	// listed ones.
+	Middleware []string `mapstructure:"middleware"`
+
+	// SchedulerDBPath is the bbolt file path backing the delayed/cron job
+	// scheduler. See Config.SchedulerDB for the default.
+	SchedulerDBPath string `mapstructure:"scheduler_db"`
+}
+
+// InitDefaults sets sane defaults for options the user did not configure.
+func (c *Config) InitDefaults() {
+	if c.NumPollers == 0 {
+		c.NumPollers = 10
+	}
+
+	if c.PipelineSize == 0 {
+		c.PipelineSize = 100_000
+	}
+}
@@ -0,0 +1,12 @@
+package jobs
+
+// SchedulerDB returns the bbolt file path backing the delayed/cron job
+// scheduler, defaulting next to the rest of RR's runtime state when the
+// user hasn't configured `jobs.scheduler_db`.
+func (c *Config) SchedulerDB() string {
+	if c.SchedulerDBPath != "" {
+		return c.SchedulerDBPath
+	}
+
+	return "rr_jobs_scheduler.db"
+}
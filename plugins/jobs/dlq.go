@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/spiral/roadrunner/v2/pkg/events"
+	"github.com/spiral/roadrunner/v2/pkg/payload"
+	priorityqueue "github.com/spiral/roadrunner/v2/pkg/priority_queue"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+// actionHeader is the response header a worker sets on payload.Payload to
+// signal retry/reject intent instead of the previous all-or-nothing
+// Ack/Nack.
+const actionHeader = "x-rr-job-action"
+
+// jobAction is the decoded contents of actionHeader, e.g.
+// {"action":"retry","delay":"5s"} or {"action":"reject"}. A missing or
+// unparsable header is treated as a plain ack.
+type jobAction struct {
+	Action string `json:"action"`
+	Delay  string `json:"delay"`
+}
+
+const (
+	actionRetry  = "retry"
+	actionReject = "reject"
+)
+
+// RetryableItem is implemented by driver Items that expose the pipeline
+// and attempt count a queued job came from (e.g. drivers/nats.Item).
+// Items that don't implement it fall back to plain Ack/Nack, same as
+// before DLQ support existed.
+type RetryableItem interface {
+	Pipeline() string
+	Attempts() int
+}
+
+// parseAction reads the retry/reject intent off a worker response; the
+// zero value (empty Action) means "ack as usual".
+func parseAction(resp payload.Payload) jobAction {
+	var act jobAction
+
+	raw, ok := resp.Headers[actionHeader]
+	if !ok {
+		return act
+	}
+
+	// a malformed header is treated the same as no header - we don't want
+	// a worker's JSON-construction bug to silently lose jobs
+	_ = json.Unmarshal(raw, &act)
+
+	return act
+}
+
+// outcome is what decideOutcome resolves a job's fate to.
+type outcome int
+
+const (
+	outcomePlainAckNack outcome = iota
+	outcomeRetry
+	outcomeDeadLetter
+)
+
+// decideOutcome is the pure decision table behind handleJobOutcome: given
+// a pipeline's DLQ config and the worker's response, what should happen
+// to the job. Kept free of the driver/pipeline types so it can be unit
+// tested without a live Item or *pipeline.Pipeline.
+func decideOutcome(maxAttempts int, dlqPipeline string, attempts int, execErr error, act jobAction) (outcome, int) {
+	// a pipeline that hasn't opted into DLQ config at all keeps the
+	// original plain ack/nack behavior - without this gate, any execution
+	// error on an unconfigured pipeline would retry forever (maxAttempts
+	// defaults to 0, i.e. unlimited) instead of nacking once.
+	if maxAttempts == 0 && dlqPipeline == "" {
+		return outcomePlainAckNack, attempts
+	}
+
+	attempts++
+
+	wantsRetry := execErr != nil || act.Action == actionRetry
+	wantsReject := act.Action == actionReject
+
+	// no failure and no retry/reject intent: ack as usual
+	if !wantsRetry && !wantsReject {
+		return outcomePlainAckNack, attempts
+	}
+
+	if wantsRetry && !wantsReject && (maxAttempts == 0 || attempts < maxAttempts) {
+		return outcomeRetry, attempts
+	}
+
+	if dlqPipeline != "" {
+		return outcomeDeadLetter, attempts
+	}
+
+	return outcomePlainAckNack, attempts
+}
+
+// handleJobOutcome is the replacement for the previous bare job.Nack()
+// call: it inspects attempts/pipeline config and either retries with
+// backoff, forwards to the dead-letter pipeline, or falls back to plain
+// Ack/Nack when the driver's Item doesn't carry enough metadata to do
+// anything smarter.
+func (p *Plugin) handleJobOutcome(ctx context.Context, job priorityqueue.Item, resp payload.Payload, execErr error) {
+	act := parseAction(resp)
+
+	ri, ok := job.(RetryableItem)
+	if !ok {
+		p.plainAckNack(job, execErr, act)
+		return
+	}
+
+	pipe, ok := p.pipelines.Load(ri.Pipeline())
+	if !ok {
+		p.plainAckNack(job, execErr, act)
+		return
+	}
+
+	ppl := pipe.(*pipeline.Pipeline)
+
+	maxAttempts := ppl.Int("max_attempts", 0)
+	dlqPipeline := ppl.String("dead_letter_pipeline", "")
+
+	decision, attempts := decideOutcome(maxAttempts, dlqPipeline, ri.Attempts(), execErr, act)
+
+	switch decision {
+	case outcomeRetry:
+		delay := parseBackoff(ppl.String("retry_backoff", ""))
+		if act.Delay != "" {
+			if d, err := time.ParseDuration(act.Delay); err == nil {
+				delay = d
+			}
+		}
+
+		p.retryJob(ctx, job, ri, ppl.Name(), attempts, delay)
+	case outcomeDeadLetter:
+		p.deadLetterJob(ctx, job, ri, dlqPipeline, attempts, execErr)
+	case outcomePlainAckNack:
+		p.plainAckNack(job, execErr, act)
+	}
+}
+
+// plainAckNack reproduces the original, pre-DLQ behavior for jobs that
+// can't be retried/dead-lettered (no RetryableItem, no pipeline, no DLQ
+// configured): ack on success, nack on anything else.
+func (p *Plugin) plainAckNack(job priorityqueue.Item, execErr error, act jobAction) {
+	if execErr == nil && act.Action != actionReject {
+		if err := job.Ack(); err != nil {
+			p.log.Error("acknowledge failed", "error", err)
+		}
+		return
+	}
+
+	if err := job.Nack(); err != nil {
+		p.log.Error("negatively acknowledge failed", "error", err)
+	}
+}
+
+// rebuildDeliveredJob recovers the original Job/Headers a delivered Item
+// carries via its Context() - the same wire shape the poller decodes - so
+// a retry/dead-letter re-delivery keeps the producer's payload exactly as
+// it was pushed (already compressed/encrypted by the producer chain)
+// instead of losing it to a bare job.Body() string.
+func rebuildDeliveredJob(job priorityqueue.Item, pipelineName string, attempts int) *structs.Job {
+	jctx := jobContext{}
+	if raw, err := job.Context(); err == nil {
+		jctx = decodeJobContext(raw)
+	}
+
+	return &structs.Job{
+		Job:     jctx.Job,
+		Payload: string(job.Body()),
+		Headers: jctx.Headers,
+		Options: &structs.Options{
+			ID:       jctx.ID,
+			Pipeline: pipelineName,
+			Attempts: attempts,
+		},
+	}
+}
+
+// retryJob re-delivers the job with an incremented attempt count, either
+// immediately or, for a non-zero backoff, through the scheduler. Either way
+// it goes out through pushRaw/the scheduler's raw path, not the producer
+// chain: the payload already went through it once on the original push,
+// and re-running middleware like gzip against an already-compressed body
+// would corrupt it.
+func (p *Plugin) retryJob(ctx context.Context, job priorityqueue.Item, ri RetryableItem, pipelineName string, attempts int, delay time.Duration) {
+	retry := rebuildDeliveredJob(job, pipelineName, attempts)
+
+	var pushErr error
+	if delay > 0 {
+		retry.Options.DelayUntil = time.Now().Add(delay)
+		_, pushErr = p.scheduler.schedule(retry, retry.Options.DelayUntil, "", true)
+	} else {
+		pushErr = p.pushRaw(ctx, retry)
+	}
+
+	if pushErr != nil {
+		p.log.Error("job retry push failed", "pipeline", pipelineName, "error", pushErr)
+	}
+
+	if err := job.Ack(); err != nil {
+		p.log.Error("acknowledge failed", "error", err)
+	}
+
+	p.events.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventJobRetried,
+		Pipeline: pipelineName,
+		Start:    time.Now(),
+	})
+}
+
+// deadLetterJob forwards the job's payload and failure reason to the
+// pipeline's configured DLQ and acks the original. Like retryJob, it goes
+// out through pushRaw rather than the producer chain, for the same reason.
+func (p *Plugin) deadLetterJob(ctx context.Context, job priorityqueue.Item, ri RetryableItem, dlqPipeline string, attempts int, execErr error) {
+	dead := rebuildDeliveredJob(job, dlqPipeline, attempts)
+
+	if dead.Headers == nil {
+		dead.Headers = map[string][]byte{}
+	}
+	if execErr != nil {
+		dead.Headers["x-rr-dlq-error"] = []byte(execErr.Error())
+	}
+
+	if err := p.pushRaw(ctx, dead); err != nil {
+		p.log.Error("dead letter push failed", "pipeline", dlqPipeline, "error", err)
+	}
+
+	if err := job.Ack(); err != nil {
+		p.log.Error("acknowledge failed", "error", err)
+	}
+
+	p.events.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventJobDeadLettered,
+		Pipeline: dlqPipeline,
+		Start:    time.Now(),
+	})
+}
+
+// parseBackoff parses a pipeline's retry_backoff option, defaulting to no
+// delay on an empty/invalid value.
+func parseBackoff(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+
+	return d
+}
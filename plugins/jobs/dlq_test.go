@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecideOutcome(t *testing.T) {
+	execErr := errors.New("worker failed")
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		dlqPipeline string
+		attempts    int
+		execErr     error
+		act         jobAction
+		wantOutcome outcome
+		wantAttempt int
+	}{
+		{
+			name:        "no DLQ config falls back to plain ack/nack on success",
+			maxAttempts: 0,
+			dlqPipeline: "",
+			attempts:    0,
+			execErr:     nil,
+			act:         jobAction{},
+			wantOutcome: outcomePlainAckNack,
+			wantAttempt: 0,
+		},
+		{
+			name:        "no DLQ config does not retry forever on error",
+			maxAttempts: 0,
+			dlqPipeline: "",
+			attempts:    3,
+			execErr:     execErr,
+			act:         jobAction{},
+			wantOutcome: outcomePlainAckNack,
+			wantAttempt: 3,
+		},
+		{
+			name:        "max_attempts set, under the limit, retries",
+			maxAttempts: 3,
+			dlqPipeline: "",
+			attempts:    0,
+			execErr:     execErr,
+			act:         jobAction{},
+			wantOutcome: outcomeRetry,
+			wantAttempt: 1,
+		},
+		{
+			name:        "max_attempts exhausted with a DLQ falls through to dead-letter",
+			maxAttempts: 3,
+			dlqPipeline: "jobs.dlq",
+			attempts:    2,
+			execErr:     execErr,
+			act:         jobAction{},
+			wantOutcome: outcomeDeadLetter,
+			wantAttempt: 3,
+		},
+		{
+			name:        "max_attempts exhausted without a DLQ falls back to plain ack/nack",
+			maxAttempts: 3,
+			dlqPipeline: "",
+			attempts:    2,
+			execErr:     execErr,
+			act:         jobAction{},
+			wantOutcome: outcomePlainAckNack,
+			wantAttempt: 3,
+		},
+		{
+			name:        "explicit reject action skips retry straight to dead-letter",
+			maxAttempts: 3,
+			dlqPipeline: "jobs.dlq",
+			attempts:    0,
+			execErr:     nil,
+			act:         jobAction{Action: actionReject},
+			wantOutcome: outcomeDeadLetter,
+			wantAttempt: 1,
+		},
+		{
+			name:        "explicit retry action retries even without an execErr",
+			maxAttempts: 5,
+			dlqPipeline: "",
+			attempts:    0,
+			execErr:     nil,
+			act:         jobAction{Action: actionRetry},
+			wantOutcome: outcomeRetry,
+			wantAttempt: 1,
+		},
+		{
+			name:        "DLQ configured with no max_attempts retries indefinitely",
+			maxAttempts: 0,
+			dlqPipeline: "jobs.dlq",
+			attempts:    50,
+			execErr:     execErr,
+			act:         jobAction{},
+			wantOutcome: outcomeRetry,
+			wantAttempt: 51,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOutcome, gotAttempt := decideOutcome(tt.maxAttempts, tt.dlqPipeline, tt.attempts, tt.execErr, tt.act)
+			if gotOutcome != tt.wantOutcome {
+				t.Fatalf("decideOutcome() outcome = %v, want %v", gotOutcome, tt.wantOutcome)
+			}
+			if gotAttempt != tt.wantAttempt {
+				t.Fatalf("decideOutcome() attempts = %d, want %d", gotAttempt, tt.wantAttempt)
+			}
+		})
+	}
+}
+
+func TestParseBackoff(t *testing.T) {
+	if d := parseBackoff(""); d != 0 {
+		t.Fatalf("parseBackoff(\"\") = %v, want 0", d)
+	}
+
+	if d := parseBackoff("not-a-duration"); d != 0 {
+		t.Fatalf("parseBackoff(invalid) = %v, want 0", d)
+	}
+
+	if d := parseBackoff("5s"); d.Seconds() != 5 {
+		t.Fatalf("parseBackoff(\"5s\") = %v, want 5s", d)
+	}
+}
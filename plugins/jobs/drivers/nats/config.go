@@ -0,0 +1,66 @@
+package nats
+
+import "time"
+
+// Config is a NATS JetStream pipeline/global configuration.
+//
+// The global `nats` section supplies defaults for addr, ack_wait,
+// max_ack_pending and pull_batch, used when a pipeline's own
+// `jobs.pipelines.<name>` config key leaves them unset; the other options
+// are read from the pipeline config only.
+type Config struct {
+	// Addr is the NATS server address, ie `nats://127.0.0.1:4222`.
+	Addr string `mapstructure:"addr"`
+
+	// Stream is the JetStream stream name backing the pipeline.
+	Stream string `mapstructure:"stream"`
+
+	// Subject is the subject the stream consumes jobs from and the
+	// driver publishes to on Push.
+	Subject string `mapstructure:"subject"`
+
+	// Consumer is the JetStream consumer (durable) name. When empty, an
+	// ephemeral pull consumer is created for the lifetime of the pipeline.
+	Consumer string `mapstructure:"consumer"`
+
+	// Durable marks the consumer as durable so it survives Stop/Run cycles.
+	Durable bool `mapstructure:"durable"`
+
+	// Priority is the pipeline default job priority, inherited by jobs
+	// pushed without an explicit priority.
+	Priority int64 `mapstructure:"priority"`
+
+	// MaxAckPending caps the number of in-flight (un-acked) messages the
+	// pull subscription will keep delivered at once.
+	MaxAckPending int `mapstructure:"max_ack_pending"`
+
+	// AckWait is how long JetStream waits for an Ack before redelivering.
+	AckWait time.Duration `mapstructure:"ack_wait"`
+
+	// MaxDeliver caps redelivery attempts before the message is dropped.
+	// Zero means unlimited.
+	MaxDeliver int `mapstructure:"max_deliver"`
+
+	// PullBatch is the number of messages requested per pull subscription
+	// fetch call.
+	PullBatch int `mapstructure:"pull_batch"`
+}
+
+// InitDefaults sets sane defaults for options the user did not configure.
+func (c *Config) InitDefaults() {
+	if c.Addr == "" {
+		c.Addr = "nats://127.0.0.1:4222"
+	}
+
+	if c.MaxAckPending == 0 {
+		c.MaxAckPending = 100
+	}
+
+	if c.AckWait == 0 {
+		c.AckWait = time.Second * 30
+	}
+
+	if c.PullBatch == 0 {
+		c.PullBatch = 10
+	}
+}
@@ -0,0 +1,243 @@
+package nats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/pkg/events"
+	priorityqueue "github.com/spiral/roadrunner/v2/pkg/priority_queue"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// consumer is the NATS JetStream implementation of jobs.Consumer.
+type consumer struct {
+	sync.Mutex
+
+	log    logger.Logger
+	pq     priorityqueue.Queue
+	events events.Handler
+
+	cfg  *Config
+	pipe *pipeline.Pipeline
+
+	conn *nats.Conn
+	js   nats.JetStreamContext
+	sub  *nats.Subscription
+
+	stopCh chan struct{}
+	paused bool
+}
+
+// NewNATSConsumer constructs a consumer for both the initial, config-driven,
+// set of pipelines (JobsConstruct) and pipelines declared at runtime
+// (FromPipeline).
+func NewNATSConsumer(configKey string, cfg *Config, log logger.Logger, eh events.Handler, pq priorityqueue.Queue) (*consumer, error) {
+	const op = errors.Op("nats_new_consumer")
+
+	cfg.InitDefaults()
+
+	conn, err := nats.Connect(cfg.Addr, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &consumer{
+		log:    log,
+		pq:     pq,
+		events: eh,
+		cfg:    cfg,
+		conn:   conn,
+		js:     js,
+		stopCh: make(chan struct{}, 1),
+	}, nil
+}
+
+// Register binds the consumer to a concrete pipeline, creating the stream
+// and durable/ephemeral pull consumer if they don't already exist.
+func (c *consumer) Register(pipe *pipeline.Pipeline) error {
+	const op = errors.Op("nats_consumer_register")
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.pipe = pipe
+
+	_, err := c.js.StreamInfo(c.cfg.Stream)
+	if err != nil {
+		_, err = c.js.AddStream(&nats.StreamConfig{
+			Name:     c.cfg.Stream,
+			Subjects: []string{c.cfg.Subject},
+		})
+		if err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	consumerCfg := &nats.ConsumerConfig{
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       c.cfg.AckWait,
+		MaxAckPending: c.cfg.MaxAckPending,
+		MaxDeliver:    c.cfg.MaxDeliver,
+	}
+
+	// only a durable consumer gets a name that survives Stop/Run cycles;
+	// an ephemeral one is torn down by JetStream once idle.
+	if c.cfg.Durable {
+		consumerCfg.Durable = c.cfg.Consumer
+	}
+
+	if _, err = c.js.AddConsumer(c.cfg.Stream, consumerCfg); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// Run starts the pull subscription loop for the pipeline.
+func (c *consumer) Run(ctx context.Context, pipe *pipeline.Pipeline) error {
+	const op = errors.Op("nats_consumer_run")
+
+	c.Lock()
+	defer c.Unlock()
+
+	start := time.Now()
+
+	sub, err := c.js.PullSubscribe(c.cfg.Subject, c.cfg.Consumer, nats.ManualAck())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	c.sub = sub
+
+	go c.poll()
+
+	c.events.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventPipeActive,
+		Pipeline: pipe.Name(),
+		Driver:   pipe.Driver(),
+		Start:    start,
+		Elapsed:  time.Since(start),
+	})
+
+	return nil
+}
+
+// poll repeatedly fetches a batch from the pull subscription and feeds
+// every message into the shared priority queue.
+func (c *consumer) poll() {
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		default:
+			c.Lock()
+			paused := c.paused
+			sub := c.sub
+			c.Unlock()
+
+			if paused || sub == nil {
+				time.Sleep(time.Millisecond * 500)
+				continue
+			}
+
+			msgs, err := sub.Fetch(c.cfg.PullBatch, nats.MaxWait(time.Second))
+			if err != nil {
+				// Fetch times out regularly when the stream is idle, that's expected.
+				if err != nats.ErrTimeout {
+					c.log.Warn("jetstream fetch", "error", err)
+				}
+				continue
+			}
+
+			for i := range msgs {
+				item, err := fromMsg(msgs[i])
+				if err != nil {
+					c.log.Error("jetstream message decode", "error", err)
+					_ = msgs[i].Nak()
+					continue
+				}
+
+				c.pq.Insert(item)
+			}
+		}
+	}
+}
+
+// Push publishes a job onto the stream subject. ctx carries the caller's
+// deadline/cancellation through to the JetStream publish ack wait.
+func (c *consumer) Push(ctx context.Context, j *structs.Job) error {
+	const op = errors.Op("nats_consumer_push")
+
+	item := fromJob(j)
+
+	body, err := encodeItem(item)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if _, err = c.js.Publish(c.cfg.Subject, body, nats.Context(ctx)); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// Pause drains the pull subscription: in-flight fetches finish, no new
+// Fetch calls are issued until Resume.
+func (c *consumer) Pause(ctx context.Context, pipeline string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.paused = true
+
+	c.events.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventPipeStopped,
+		Pipeline: pipeline,
+		Driver:   "nats",
+		Start:    time.Now(),
+	})
+}
+
+// Resume re-enables the pull loop.
+func (c *consumer) Resume(ctx context.Context, pipeline string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.paused = false
+
+	c.events.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventPipeActive,
+		Pipeline: pipeline,
+		Driver:   "nats",
+		Start:    time.Now(),
+	})
+}
+
+// Stop tears down the subscription and the underlying connection.
+func (c *consumer) Stop(_ context.Context) error {
+	c.stopCh <- struct{}{}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.sub != nil {
+		_ = c.sub.Unsubscribe()
+	}
+
+	c.conn.Close()
+
+	return nil
+}
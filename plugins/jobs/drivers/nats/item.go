@@ -0,0 +1,123 @@
+package nats
+
+import (
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+// Item is a JetStream-backed priority_queue.Item: it wraps a *structs.Job
+// together with the raw JetStream message (nil for jobs produced locally
+// via Push, set for jobs pulled from the subscription) so Ack/Nack can be
+// translated into native JetStream semantics.
+type Item struct {
+	Job     string            `json:"job"`
+	Ident   string            `json:"id"`
+	Payload string            `json:"payload"`
+	Headers map[string][]byte `json:"headers,omitempty"`
+	Options *Options          `json:"options,omitempty"`
+
+	// msg is non-nil for items obtained from the pull subscription.
+	msg *nats.Msg
+}
+
+// Options configure the consumption behavior of a single job.
+type Options struct {
+	Priority int64  `json:"priority"`
+	Pipeline string `json:"pipeline"`
+	Delay    int64  `json:"delay"`
+	Attempts int    `json:"attempts"`
+}
+
+func (i *Item) ID() string {
+	return i.Ident
+}
+
+func (i *Item) Priority() int64 {
+	return i.Options.Priority
+}
+
+// Pipeline and Attempts implement jobs.RetryableItem, letting the DLQ
+// logic in dlq.go retry/dead-letter a message instead of falling back to
+// a plain Ack/Nack.
+func (i *Item) Pipeline() string {
+	return i.Options.Pipeline
+}
+
+func (i *Item) Attempts() int {
+	return i.Options.Attempts
+}
+
+func (i *Item) Body() []byte {
+	return []byte(i.Payload)
+}
+
+// Context packs the job metadata the worker expects on the payload.Context.
+func (i *Item) Context() ([]byte, error) {
+	ctx, err := json.Marshal(struct {
+		Job      string            `json:"job"`
+		ID       string            `json:"id"`
+		Headers  map[string][]byte `json:"headers,omitempty"`
+		Pipeline string            `json:"pipeline"`
+	}{Job: i.Job, ID: i.Ident, Headers: i.Headers, Pipeline: i.Options.Pipeline})
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// Ack acknowledges the underlying JetStream message, if any. Jobs pushed
+// without ever being pulled (shouldn't normally happen) are a no-op.
+func (i *Item) Ack() error {
+	if i.msg == nil {
+		return nil
+	}
+
+	return i.msg.Ack()
+}
+
+// Nack sends a JetStream Nak, which makes the message immediately
+// available for redelivery (subject to the consumer's MaxDeliver/backoff).
+func (i *Item) Nack() error {
+	if i.msg == nil {
+		return nil
+	}
+
+	return i.msg.Nak()
+}
+
+// fromMsg builds an Item from a raw JetStream pull message.
+func fromMsg(msg *nats.Msg) (*Item, error) {
+	item := &Item{}
+	if err := json.Unmarshal(msg.Data, item); err != nil {
+		return nil, err
+	}
+
+	item.msg = msg
+
+	return item, nil
+}
+
+// encodeItem serializes an Item to the wire format stored in the stream.
+func encodeItem(i *Item) ([]byte, error) {
+	return json.Marshal(i)
+}
+
+// fromJob converts an inbound structs.Job into the wire representation
+// published to the stream.
+func fromJob(j *structs.Job) *Item {
+	return &Item{
+		Job:     j.Job,
+		Ident:   j.Options.ID,
+		Payload: j.Payload,
+		Headers: j.Headers,
+		Options: &Options{
+			Priority: j.Options.Priority,
+			Pipeline: j.Options.Pipeline,
+			Delay:    j.Options.Delay,
+			Attempts: j.Options.Attempts,
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+func TestFromJobRoundTripsThroughEncodeAndFromMsg(t *testing.T) {
+	j := &structs.Job{
+		Job:     "some.job",
+		Payload: `{"foo":"bar"}`,
+		Headers: map[string][]byte{"x-trace": []byte("abc")},
+		Options: &structs.Options{
+			ID:       "job-1",
+			Priority: 5,
+			Pipeline: "test-local",
+			Delay:    10,
+			Attempts: 2,
+		},
+	}
+
+	item := fromJob(j)
+
+	body, err := encodeItem(item)
+	if err != nil {
+		t.Fatalf("encodeItem() error = %v", err)
+	}
+
+	decoded, err := fromMsg(&nats.Msg{Data: body})
+	if err != nil {
+		t.Fatalf("fromMsg() error = %v", err)
+	}
+
+	if decoded.ID() != j.Options.ID {
+		t.Fatalf("decoded.ID() = %q, want %q", decoded.ID(), j.Options.ID)
+	}
+
+	if decoded.Priority() != j.Options.Priority {
+		t.Fatalf("decoded.Priority() = %d, want %d", decoded.Priority(), j.Options.Priority)
+	}
+
+	if decoded.Pipeline() != j.Options.Pipeline {
+		t.Fatalf("decoded.Pipeline() = %q, want %q", decoded.Pipeline(), j.Options.Pipeline)
+	}
+
+	if decoded.Attempts() != j.Options.Attempts {
+		t.Fatalf("decoded.Attempts() = %d, want %d", decoded.Attempts(), j.Options.Attempts)
+	}
+
+	if string(decoded.Body()) != j.Payload {
+		t.Fatalf("decoded.Body() = %q, want %q", decoded.Body(), j.Payload)
+	}
+}
+
+func TestItemContextCarriesJobHeadersAndPipeline(t *testing.T) {
+	item := &Item{
+		Job:     "some.job",
+		Ident:   "job-1",
+		Headers: map[string][]byte{"x-trace": []byte("abc")},
+		Options: &Options{Pipeline: "test-local"},
+	}
+
+	raw, err := item.Context()
+	if err != nil {
+		t.Fatalf("Context() error = %v", err)
+	}
+
+	var decoded struct {
+		Job      string            `json:"job"`
+		ID       string            `json:"id"`
+		Headers  map[string][]byte `json:"headers,omitempty"`
+		Pipeline string            `json:"pipeline"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if decoded.Job != item.Job || decoded.ID != item.Ident || decoded.Pipeline != item.Options.Pipeline {
+		t.Fatalf("Context() = %+v, want job/id/pipeline to match the source item", decoded)
+	}
+
+	if string(decoded.Headers["x-trace"]) != "abc" {
+		t.Fatalf("Context() headers = %v, want x-trace=abc", decoded.Headers)
+	}
+}
+
+func TestItemAckNackAreNoOpsWithoutAnUnderlyingMessage(t *testing.T) {
+	item := &Item{}
+
+	if err := item.Ack(); err != nil {
+		t.Fatalf("Ack() on a locally-produced item error = %v, want nil", err)
+	}
+
+	if err := item.Nack(); err != nil {
+		t.Fatalf("Nack() on a locally-produced item error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,121 @@
+package nats
+
+import (
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/pkg/events"
+	priorityqueue "github.com/spiral/roadrunner/v2/pkg/priority_queue"
+	"github.com/spiral/roadrunner/v2/plugins/config"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// PluginName is the driver name used in pipeline configuration
+// (`jobs.pipelines.<name>.driver: nats`) and registered with the jobs
+// plugin via CollectMQBrokers.
+const PluginName string = "nats"
+
+const configKey string = "nats"
+
+// Plugin exposes NATS JetStream as a jobs.Constructor: it is picked up by
+// the jobs plugin's endure DI graph and collected through CollectMQBrokers,
+// the same way the AMQP/SQS/ephemeral drivers are.
+type Plugin struct {
+	cfg       *Config
+	log       logger.Logger
+	cfgPlugin config.Configurer
+}
+
+// Init reads the global `nats` config section (connection address,
+// defaults shared by pipelines that don't override them).
+func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
+	const op = errors.Op("nats_plugin_init")
+
+	if !cfg.Has(configKey) {
+		return errors.E(op, errors.Disabled)
+	}
+
+	p.cfg = &Config{}
+	if err := cfg.UnmarshalKey(configKey, p.cfg); err != nil {
+		return errors.E(op, err)
+	}
+
+	p.cfg.InitDefaults()
+	p.cfgPlugin = cfg
+	p.log = log
+
+	return nil
+}
+
+// Name returns the driver name under which the jobs plugin registers it.
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Available signals this is an optional, DI-collected plugin.
+func (p *Plugin) Available() {}
+
+// JobsConstruct builds a consumer for one of the initial, config-declared
+// pipelines.
+func (p *Plugin) JobsConstruct(key string, eh events.Handler, pq priorityqueue.Queue) (jobs.Consumer, error) {
+	const op = errors.Op("nats_jobs_construct")
+
+	pipeCfg := &Config{}
+	if err := p.cfgPlugin.UnmarshalKey(key, pipeCfg); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	mergeDefaults(pipeCfg, p.cfg)
+
+	c, err := NewNATSConsumer(key, pipeCfg, p.log, eh, pq)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return c, nil
+}
+
+// FromPipeline builds a consumer for a pipeline declared at runtime via
+// the RPC Declare call.
+func (p *Plugin) FromPipeline(pipe *pipeline.Pipeline, eh events.Handler, pq priorityqueue.Queue) (jobs.Consumer, error) {
+	const op = errors.Op("nats_from_pipeline")
+
+	pipeCfg := &Config{
+		Stream:        pipe.String("stream", ""),
+		Subject:       pipe.String("subject", ""),
+		Consumer:      pipe.String("consumer", pipe.Name()),
+		Durable:       pipe.Bool("durable", false),
+		Priority:      int64(pipe.Priority()),
+		MaxAckPending: pipe.Int("max_ack_pending", 0),
+		MaxDeliver:    pipe.Int("max_deliver", 0),
+	}
+
+	mergeDefaults(pipeCfg, p.cfg)
+
+	c, err := NewNATSConsumer(pipe.Name(), pipeCfg, p.log, eh, pq)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return c, nil
+}
+
+// mergeDefaults fills zero-value fields of a pipeline-specific config from
+// the plugin-wide defaults.
+func mergeDefaults(pipeCfg, defaults *Config) {
+	if pipeCfg.Addr == "" {
+		pipeCfg.Addr = defaults.Addr
+	}
+
+	if pipeCfg.AckWait == 0 {
+		pipeCfg.AckWait = defaults.AckWait
+	}
+
+	if pipeCfg.MaxAckPending == 0 {
+		pipeCfg.MaxAckPending = defaults.MaxAckPending
+	}
+
+	if pipeCfg.PullBatch == 0 {
+		pipeCfg.PullBatch = defaults.PullBatch
+	}
+}
@@ -0,0 +1,279 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/pkg/events"
+	priorityqueue "github.com/spiral/roadrunner/v2/pkg/priority_queue"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+const (
+	backoffBaseline = time.Millisecond * 250
+	backoffCap      = time.Second * 30
+	// defaultFailLimit is the number of consecutive rebuild attempts the
+	// handle tolerates before giving up and surfacing the error to the caller.
+	defaultFailLimit = 5
+)
+
+// lazyHandle wraps a jobs.Consumer so a dropped transport (RabbitMQ TCP
+// reset, SQS 5xx storm, Beanstalk EPIPE) doesn't take the pipeline down:
+// on error it rebuilds the driver from the stored jobs.Constructor and
+// retries with exponential backoff before giving up.
+type lazyHandle struct {
+	mu sync.RWMutex
+
+	name      string
+	construct jobs.Constructor
+	configKey string
+	pipe      *pipeline.Pipeline
+	eh        events.Handler
+	pq        priorityqueue.Queue
+	log       logger.Logger
+
+	driver jobs.Consumer
+
+	failLimit int
+	spoolDir  string
+}
+
+// newLazyHandle wraps an already-initialized driver. configKey is empty
+// when the pipeline was declared at runtime (FromPipeline path), set when
+// it came from the initial config (JobsConstruct path).
+func newLazyHandle(name string, driver jobs.Consumer, c jobs.Constructor, configKey string, pipe *pipeline.Pipeline, eh events.Handler, pq priorityqueue.Queue, log logger.Logger) *lazyHandle {
+	return &lazyHandle{
+		name:      name,
+		construct: c,
+		configKey: configKey,
+		pipe:      pipe,
+		eh:        eh,
+		pq:        pq,
+		log:       log,
+		driver:    driver,
+		failLimit: defaultFailLimit,
+		spoolDir:  filepath.Join(os.TempDir(), "rr-jobs-spool"),
+	}
+}
+
+// Register delegates directly: re-registering on reconnect happens as part
+// of rebuild(), not on every call.
+func (h *lazyHandle) Register(pipe *pipeline.Pipeline) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.driver.Register(pipe)
+}
+
+func (h *lazyHandle) Run(ctx context.Context, pipe *pipeline.Pipeline) error {
+	return h.withRetry(ctx, func(d jobs.Consumer) error {
+		return d.Run(ctx, pipe)
+	})
+}
+
+func (h *lazyHandle) Pause(ctx context.Context, pipeline string) {
+	h.mu.RLock()
+	d := h.driver
+	h.mu.RUnlock()
+
+	d.Pause(ctx, pipeline)
+}
+
+func (h *lazyHandle) Resume(ctx context.Context, pipeline string) {
+	h.mu.RLock()
+	d := h.driver
+	h.mu.RUnlock()
+
+	d.Resume(ctx, pipeline)
+}
+
+func (h *lazyHandle) Stop(ctx context.Context) error {
+	h.mu.RLock()
+	d := h.driver
+	h.mu.RUnlock()
+
+	return d.Stop(ctx)
+}
+
+// SupportsDelay reports whether the wrapped driver implements
+// NativeDelayer and supports delay, so Plugin.Push sees through the handle.
+func (h *lazyHandle) SupportsDelay() bool {
+	h.mu.RLock()
+	d := h.driver
+	h.mu.RUnlock()
+
+	nd, ok := d.(NativeDelayer)
+	return ok && nd.SupportsDelay()
+}
+
+// Push either forwards to the live driver, or - while a rebuild is in
+// flight - blocks until ctx's deadline or spools the job to disk so the
+// producer doesn't lose it.
+func (h *lazyHandle) Push(ctx context.Context, j *structs.Job) error {
+	err := h.withRetry(ctx, func(d jobs.Consumer) error {
+		return d.Push(ctx, j)
+	})
+	if err == nil {
+		return nil
+	}
+
+	if spoolErr := h.spool(j); spoolErr != nil {
+		return errors.E(errors.Op("lazy_handle_push"), err)
+	}
+
+	return nil
+}
+
+// withRetry rebuilds the driver on failure with exponential backoff, up to
+// failLimit attempts, and gives up early if ctx is done.
+func (h *lazyHandle) withRetry(ctx context.Context, fn func(jobs.Consumer) error) error {
+	const op = errors.Op("lazy_handle_retry")
+
+	h.mu.RLock()
+	d := h.driver
+	h.mu.RUnlock()
+
+	err := fn(d)
+	if err == nil {
+		return nil
+	}
+
+	h.log.Warn("driver call failed, entering recovery", "pipeline", h.name, "error", err)
+	h.eh.Push(events.JobEvent{
+		Ctx:      ctx,
+		Event:    events.EventDriverDegraded,
+		Pipeline: h.name,
+		Error:    err,
+		Start:    time.Now(),
+	})
+
+	backoff := backoffBaseline
+	for attempt := 0; attempt < h.failLimit; attempt++ {
+		select {
+		case <-ctx.Done():
+			return errors.E(op, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		rebuilt, rerr := h.rebuild()
+		if rerr != nil {
+			h.log.Warn("driver rebuild failed", "pipeline", h.name, "attempt", attempt+1, "error", rerr)
+			backoff *= 2
+			if backoff > backoffCap {
+				backoff = backoffCap
+			}
+			continue
+		}
+
+		if err = fn(rebuilt); err == nil {
+			h.eh.Push(events.JobEvent{
+				Ctx:      ctx,
+				Event:    events.EventDriverRecovered,
+				Pipeline: h.name,
+				Start:    time.Now(),
+			})
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > backoffCap {
+			backoff = backoffCap
+		}
+	}
+
+	return errors.E(op, errors.Errorf("driver %s did not recover after %d attempts: %w", h.name, h.failLimit, err))
+}
+
+// rebuild constructs a fresh driver instance from the stored constructor
+// and swaps it in, re-registering the pipeline.
+func (h *lazyHandle) rebuild() (jobs.Consumer, error) {
+	const op = errors.Op("lazy_handle_rebuild")
+
+	var d jobs.Consumer
+	var err error
+
+	if h.configKey != "" {
+		d, err = h.construct.JobsConstruct(h.configKey, h.eh, h.pq)
+	} else {
+		d, err = h.construct.FromPipeline(h.pipe, h.eh, h.pq)
+	}
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if err = d.Register(h.pipe); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	h.mu.Lock()
+	h.driver = d
+	h.mu.Unlock()
+
+	h.drainSpool(d)
+
+	return d, nil
+}
+
+// drainSpool replays every job spooled to disk while the driver was down
+// through the freshly rebuilt driver. A job is only removed from the
+// spool once Push for it succeeds, so a driver that goes down again
+// mid-drain just leaves the remainder for the next successful rebuild.
+func (h *lazyHandle) drainSpool(d jobs.Consumer) {
+	matches, err := filepath.Glob(filepath.Join(h.spoolDir, h.name+"-*.job"))
+	if err != nil || len(matches) == 0 {
+		return
+	}
+
+	for _, path := range matches {
+		raw, rerr := os.ReadFile(path)
+		if rerr != nil {
+			h.log.Warn("spool file read failed", "pipeline", h.name, "path", path, "error", rerr)
+			continue
+		}
+
+		j := &structs.Job{}
+		if rerr = json.Unmarshal(raw, j); rerr != nil {
+			h.log.Error("spool file decode failed, dropping", "pipeline", h.name, "path", path, "error", rerr)
+			_ = os.Remove(path)
+			continue
+		}
+
+		if rerr = d.Push(context.Background(), j); rerr != nil {
+			h.log.Warn("spooled job replay failed, will retry on next recovery", "pipeline", h.name, "path", path, "error", rerr)
+			continue
+		}
+
+		if rerr = os.Remove(path); rerr != nil {
+			h.log.Warn("spool file cleanup failed", "pipeline", h.name, "path", path, "error", rerr)
+		}
+	}
+}
+
+// spool persists a job that couldn't be delivered during recovery to an
+// on-disk overflow directory; drainSpool, run after every successful
+// rebuild, is what actually replays it.
+func (h *lazyHandle) spool(j *structs.Job) error {
+	const op = errors.Op("lazy_handle_spool")
+
+	if err := os.MkdirAll(h.spoolDir, 0o755); err != nil {
+		return errors.E(op, err)
+	}
+
+	f, err := os.CreateTemp(h.spoolDir, h.name+"-*.job")
+	if err != nil {
+		return errors.E(op, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return json.NewEncoder(f).Encode(j)
+}
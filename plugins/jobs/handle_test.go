@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiral/roadrunner/v2/plugins/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// nopLogger discards the log lines drainSpool writes on its failure paths.
+type nopLogger struct{ logger.Logger }
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// fakeConsumer is a minimal jobs.Consumer double that records every Push
+// and can be told to fail them, for exercising lazyHandle's spool/drain
+// path without a real driver.
+type fakeConsumer struct {
+	pushErr error
+	pushed  []*structs.Job
+}
+
+func (f *fakeConsumer) Register(*pipeline.Pipeline) error  { return nil }
+func (f *fakeConsumer) Run(context.Context, *pipeline.Pipeline) error { return nil }
+func (f *fakeConsumer) Pause(context.Context, string)       {}
+func (f *fakeConsumer) Resume(context.Context, string)      {}
+func (f *fakeConsumer) Stop(context.Context) error          { return nil }
+
+func (f *fakeConsumer) Push(_ context.Context, j *structs.Job) error {
+	if f.pushErr != nil {
+		return f.pushErr
+	}
+	f.pushed = append(f.pushed, j)
+	return nil
+}
+
+func newTestHandle(t *testing.T) *lazyHandle {
+	t.Helper()
+
+	return &lazyHandle{
+		name:      "test-local",
+		log:       nopLogger{},
+		failLimit: defaultFailLimit,
+		spoolDir:  t.TempDir(),
+	}
+}
+
+func TestSpoolThenDrainSpoolReplaysAndRemovesTheFile(t *testing.T) {
+	h := newTestHandle(t)
+
+	j := &structs.Job{Payload: "hello", Options: &structs.Options{Pipeline: h.name}}
+	if err := h.spool(j); err != nil {
+		t.Fatalf("spool() error = %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(h.spoolDir, h.name+"-*.job"))
+	if len(matches) != 1 {
+		t.Fatalf("spool() left %d files, want 1", len(matches))
+	}
+
+	d := &fakeConsumer{}
+	h.drainSpool(d)
+
+	if len(d.pushed) != 1 || d.pushed[0].Payload != "hello" {
+		t.Fatalf("drainSpool() pushed %+v, want one job with payload %q", d.pushed, "hello")
+	}
+
+	matches, _ = filepath.Glob(filepath.Join(h.spoolDir, h.name+"-*.job"))
+	if len(matches) != 0 {
+		t.Fatalf("drainSpool() left %d files behind after a successful push, want 0", len(matches))
+	}
+}
+
+func TestDrainSpoolKeepsTheFileWhenPushFails(t *testing.T) {
+	h := newTestHandle(t)
+
+	j := &structs.Job{Payload: "hello", Options: &structs.Options{Pipeline: h.name}}
+	if err := h.spool(j); err != nil {
+		t.Fatalf("spool() error = %v", err)
+	}
+
+	d := &fakeConsumer{pushErr: context.DeadlineExceeded}
+	h.drainSpool(d)
+
+	matches, _ := filepath.Glob(filepath.Join(h.spoolDir, h.name+"-*.job"))
+	if len(matches) != 1 {
+		t.Fatalf("drainSpool() left %d files after a failed push, want 1 (kept for next recovery)", len(matches))
+	}
+}
+
+func TestDrainSpoolDropsAnUndecodableFile(t *testing.T) {
+	h := newTestHandle(t)
+
+	if err := os.MkdirAll(h.spoolDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(h.spoolDir, h.name+"-garbage.job"), []byte("not json"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	d := &fakeConsumer{}
+	h.drainSpool(d)
+
+	if len(d.pushed) != 0 {
+		t.Fatalf("drainSpool() pushed %d jobs from an undecodable file, want 0", len(d.pushed))
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(h.spoolDir, h.name+"-*.job"))
+	if len(matches) != 0 {
+		t.Fatalf("drainSpool() left %d undecodable files behind, want them dropped", len(matches))
+	}
+}
+
+func TestDrainSpoolOnEmptySpoolIsANoOp(t *testing.T) {
+	h := newTestHandle(t)
+
+	d := &fakeConsumer{}
+	h.drainSpool(d)
+
+	if len(d.pushed) != 0 {
+		t.Fatalf("drainSpool() on an empty spool pushed %d jobs, want 0", len(d.pushed))
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+// noopNext terminates a consume-side chain where there's no push call to
+// make; it exists purely so the same chain() helper can be reused for both
+// the push and consume paths.
+func noopNext(context.Context, *structs.Job) error {
+	return nil
+}
+
+// chain builds a single jobs.Next out of the plugin's ordered middleware
+// set, terminated by final. Middlewares run in p.middleware order (as
+// arranged by orderMiddleware), each wrapping the next.
+func (p *Plugin) chain(final jobs.Next) jobs.Next {
+	next := final
+
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		mw := p.middleware[i]
+		n := next
+		next = func(ctx context.Context, j *structs.Job) error {
+			return mw.Process(ctx, j, n)
+		}
+	}
+
+	return next
+}
+
+// CollectMiddleware is the DI collector counterpart to CollectMQBrokers:
+// every plugin implementing jobs.Middleware is gathered here by endure and
+// ordered afterwards according to the `jobs.middleware` config list.
+// middlewareOrder records the order endure handed them to us in, so the
+// default chain order (no config) is deterministic across restarts.
+func (p *Plugin) CollectMiddleware(name endure.Named, mw jobs.Middleware) {
+	p.middlewareByName[name.Name()] = mw
+	p.middlewareOrder = append(p.middlewareOrder, name.Name())
+}
+
+// orderMiddleware arranges the collected middleware according to the
+// user-configured order, then appends every registered middleware that
+// wasn't listed, in registration order (middlewareOrder) - map iteration
+// order is randomized per process, so that fallback must not build the
+// chain by ranging over middlewareByName directly.
+func (p *Plugin) orderMiddleware() {
+	listed := make(map[string]struct{}, len(p.cfg.Middleware))
+
+	for _, name := range p.cfg.Middleware {
+		if mw, ok := p.middlewareByName[name]; ok {
+			p.middleware = append(p.middleware, mw)
+			listed[name] = struct{}{}
+		}
+	}
+
+	for _, name := range p.middlewareOrder {
+		if _, ok := listed[name]; ok {
+			continue
+		}
+		p.middleware = append(p.middleware, p.middlewareByName[name])
+	}
+}
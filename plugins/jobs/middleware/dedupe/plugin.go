@@ -0,0 +1,129 @@
+// Package dedupe is a builtin jobs.Middleware that rejects jobs carrying
+// an idempotency key already seen within the configured TTL, so an
+// at-least-once driver (or a retrying producer) doesn't double-process a
+// job.
+package dedupe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/plugins/config"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// PluginName is the middleware name used in the `jobs.middleware` order
+// list and registered with the jobs plugin via CollectMiddleware.
+const PluginName string = "dedupe"
+
+const configKey string = "dedupe"
+
+// idempotencyHeader is the job header carrying the caller-supplied key.
+const idempotencyHeader string = "idempotency-key"
+
+// Config configures the builtin in-memory store. A Redis-backed Store can
+// be substituted by a third-party plugin implementing the same Store
+// interface and registering under the same middleware name.
+type Config struct {
+	// TTL is how long a seen key is remembered before it can be reused.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.TTL == 0 {
+		c.TTL = time.Minute * 10
+	}
+}
+
+// Store records idempotency keys that have already been processed.
+// Seen reports whether the key was already present, marking it seen
+// as a side effect.
+type Store interface {
+	Seen(key string) bool
+}
+
+type Plugin struct {
+	cfg   *Config
+	log   logger.Logger
+	store Store
+}
+
+func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
+	const op = errors.Op("dedupe_middleware_init")
+
+	p.cfg = &Config{}
+	if cfg.Has(configKey) {
+		if err := cfg.UnmarshalKey(configKey, p.cfg); err != nil {
+			return errors.E(op, err)
+		}
+	}
+	p.cfg.InitDefaults()
+
+	p.log = log
+	p.store = newMemoryStore(p.cfg.TTL)
+
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+// Process drops jobs whose idempotency key has already been seen instead
+// of forwarding them down the chain. It only runs on the consume leg: the
+// same chain also runs on the push leg before the driver accepts the
+// job, and for a pipeline a process both produces to and consumes from
+// (ephemeral, typically), checking Seen on both legs would mark the key
+// seen at push and then drop the job's only consume-side delivery.
+func (p *Plugin) Process(ctx context.Context, j *structs.Job, next jobs.Next) error {
+	if structs.DirectionOf(ctx) != structs.ConsumeDirection {
+		return next(ctx, j)
+	}
+
+	key := string(j.Headers[idempotencyHeader])
+	if key == "" {
+		return next(ctx, j)
+	}
+
+	if p.store.Seen(key) {
+		p.log.Debug("duplicate job dropped", "key", key, "pipeline", j.Options.Pipeline)
+		return nil
+	}
+
+	return next(ctx, j)
+}
+
+// memoryStore is the default, process-local Store.
+type memoryStore struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[string]time.Time
+}
+
+func newMemoryStore(ttl time.Duration) *memoryStore {
+	return &memoryStore{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+func (m *memoryStore) Seen(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	if expires, ok := m.seen[key]; ok && now.Before(expires) {
+		return true
+	}
+
+	m.seen[key] = now.Add(m.ttl)
+
+	return false
+}
@@ -0,0 +1,70 @@
+package dedupe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+func TestMemoryStoreSeenMarksKeysAndHonorsTTL(t *testing.T) {
+	m := newMemoryStore(time.Minute)
+
+	if m.Seen("key-1") {
+		t.Fatal("Seen() on a fresh key = true, want false")
+	}
+
+	if !m.Seen("key-1") {
+		t.Fatal("Seen() on an already-seen key = false, want true")
+	}
+}
+
+func TestMemoryStoreSeenForgetsExpiredKeys(t *testing.T) {
+	m := newMemoryStore(-time.Minute)
+
+	if m.Seen("key-1") {
+		t.Fatal("Seen() on a fresh key = true, want false")
+	}
+
+	if m.Seen("key-1") {
+		t.Fatal("Seen() on a key whose TTL already elapsed = true, want false (forgotten)")
+	}
+}
+
+func TestProcessOnlyChecksSeenOnTheConsumeLeg(t *testing.T) {
+	store := newMemoryStore(time.Minute)
+	p := &Plugin{store: store}
+
+	j := &structs.Job{Headers: map[string][]byte{idempotencyHeader: []byte("dup-key")}}
+	noop := func(context.Context, *structs.Job) error { return nil }
+
+	// push leg: must not mark the key seen, or the job's only consume-side
+	// delivery would be dropped for any pipeline a process both produces
+	// to and consumes from
+	if err := p.Process(structs.WithDirection(context.Background(), structs.PushDirection), j, noop); err != nil {
+		t.Fatalf("Process() on the push leg error = %v", err)
+	}
+	if store.Seen("dup-key") {
+		t.Fatal("push leg marked the key seen, want it untouched until the consume leg")
+	}
+	// Seen() above is itself a read-and-mark - undo it before the real check.
+	delete(store.seen, "dup-key")
+
+	calls := 0
+	countingNext := func(context.Context, *structs.Job) error {
+		calls++
+		return nil
+	}
+
+	// first consume-side delivery: not seen yet, forwarded and marked
+	if err := p.Process(structs.WithDirection(context.Background(), structs.ConsumeDirection), j, countingNext); err != nil {
+		t.Fatalf("Process() on the first consume delivery error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("first consume delivery called next %d times, want 1", calls)
+	}
+	if !store.Seen("dup-key") {
+		t.Fatal("consume leg did not mark the key seen")
+	}
+}
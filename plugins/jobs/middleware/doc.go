@@ -0,0 +1,14 @@
+// Package middleware collects the builtin jobs.Middleware implementations
+// shipped with the jobs plugin (gzip, otel-tracing, dedupe).
+//
+// A third-party plugin registers its own middleware the same way a driver
+// registers a broker: implement `Name() string`, an `Init` method endure
+// can satisfy from the DI container, and
+//
+//	Process(ctx context.Context, j *structs.Job, next jobs.Next) error
+//
+// to match common/jobs.Middleware. Endure then wires it into the jobs
+// plugin automatically through its CollectMiddleware collector - no
+// registration call is needed beyond adding the plugin to the application's
+// plugin list and naming it in `jobs.middleware` if order matters.
+package middleware
@@ -0,0 +1,95 @@
+// Package gzip is a builtin jobs.Middleware that transparently
+// compresses job payloads on the push path and decompresses them on the
+// consume path, so large payloads don't pay full transport cost on
+// drivers without native compression (ephemeral, beanstalk, ...).
+package gzip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// PluginName is the middleware name used in the `jobs.middleware` order
+// list and registered with the jobs plugin via CollectMiddleware.
+const PluginName string = "gzip"
+
+// magic prefixes a compressed payload so Process can tell whether a given
+// job has already been through this middleware (consume path re-entrancy,
+// or a payload produced before the middleware was enabled).
+var magic = []byte("\x1f\x8b")
+
+type Plugin struct {
+	log logger.Logger
+}
+
+func (p *Plugin) Init(log logger.Logger) error {
+	p.log = log
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+// Process compresses the payload on the push leg; on the consume leg it
+// decompresses a payload that starts with the gzip magic prefix and leaves
+// any other payload untouched. It then hands off to the rest of the chain.
+func (p *Plugin) Process(ctx context.Context, j *structs.Job, next jobs.Next) error {
+	const op = errors.Op("gzip_middleware_process")
+
+	body := []byte(j.Payload)
+	dir := structs.DirectionOf(ctx)
+
+	switch {
+	case dir == structs.ConsumeDirection && len(body) >= 2 && bytes.Equal(body[:2], magic):
+		out, err := decompress(body)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		j.Payload = string(out)
+	case dir == structs.PushDirection:
+		out, err := compress(body)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		j.Payload = string(out)
+	}
+
+	return next(ctx, j)
+}
+
+func compress(in []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(in); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(in []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(in))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,34 @@
+package gzip
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressThenDecompressRoundTrips(t *testing.T) {
+	in := []byte(`{"foo":"bar","n":1234567890}`)
+
+	compressed, err := compress(in)
+	if err != nil {
+		t.Fatalf("compress() error = %v", err)
+	}
+
+	if !bytes.Equal(compressed[:2], magic) {
+		t.Fatalf("compress() output doesn't start with the gzip magic prefix: %x", compressed[:2])
+	}
+
+	out, err := decompress(compressed)
+	if err != nil {
+		t.Fatalf("decompress() error = %v", err)
+	}
+
+	if !bytes.Equal(out, in) {
+		t.Fatalf("decompress(compress(in)) = %q, want %q", out, in)
+	}
+}
+
+func TestDecompressOnNonGzipInputErrors(t *testing.T) {
+	if _, err := decompress([]byte("not gzip data")); err == nil {
+		t.Fatal("decompress() on non-gzip input expected an error, got nil")
+	}
+}
@@ -0,0 +1,93 @@
+// Package otel is a builtin jobs.Middleware that injects an OpenTelemetry
+// trace context into the job headers so the consuming worker can continue
+// the producer's trace instead of starting a disconnected one.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/spiral/roadrunner/v2/common/jobs"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// PluginName is the middleware name used in the `jobs.middleware` order
+// list and registered with the jobs plugin via CollectMiddleware.
+const PluginName string = "otel-tracing"
+
+// tracerName identifies spans produced by this middleware in exported traces.
+const tracerName string = "jobs"
+
+type Plugin struct {
+	log    logger.Logger
+	tracer trace.Tracer
+}
+
+func (p *Plugin) Init(log logger.Logger) error {
+	p.log = log
+	p.tracer = otel.Tracer(tracerName)
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+// Process starts (push path) or continues (consume path, once the job
+// carries propagated headers) a span named after the job's pipeline.
+func (p *Plugin) Process(ctx context.Context, j *structs.Job, next jobs.Next) error {
+	if j.Headers == nil {
+		j.Headers = make(map[string][]byte)
+	}
+
+	if j.Options == nil {
+		j.Options = &structs.Options{}
+	}
+
+	carrier := headerCarrier(j.Headers)
+
+	// consume path: the job already carries a remote span context
+	if len(j.Headers) > 0 {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	}
+
+	ctx, span := p.tracer.Start(ctx, "job:"+j.Options.Pipeline)
+	defer span.End()
+
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	if err := next(ctx, j); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// headerCarrier adapts the job's byte-valued header map to
+// propagation.TextMapCarrier, which otel's propagators expect.
+type headerCarrier map[string][]byte
+
+func (h headerCarrier) Get(key string) string {
+	return string(h[key])
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = []byte(value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
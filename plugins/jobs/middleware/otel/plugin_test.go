@@ -0,0 +1,24 @@
+package otel
+
+import "testing"
+
+func TestHeaderCarrierGetSetKeys(t *testing.T) {
+	h := headerCarrier{}
+
+	if got := h.Get("traceparent"); got != "" {
+		t.Fatalf("Get() on an empty carrier = %q, want empty", got)
+	}
+
+	h.Set("traceparent", "00-abc-def-01")
+
+	if got := h.Get("traceparent"); got != "00-abc-def-01" {
+		t.Fatalf("Get() after Set() = %q, want %q", got, "00-abc-def-01")
+	}
+
+	h.Set("tracestate", "vendor=value")
+
+	keys := h.Keys()
+	if len(keys) != 2 {
+		t.Fatalf("Keys() = %v, want 2 entries", keys)
+	}
+}
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -32,6 +33,18 @@ type Plugin struct {
 	cfg *Config `mapstructure:"jobs"`
 	log logger.Logger
 
+	// pollerLog is a named sub-logger ("jobs.poller") scoping every log
+	// line the consume-side poller goroutines emit.
+	pollerLog logger.Logger
+
+	// rootCtx/rootCancel bound the plugin's lifetime; it's the context
+	// Serve and the poller goroutines hand to driver calls (RPC calls carry
+	// their own caller-supplied context; the scheduler and the spool replay
+	// use context.Background()), so Stop cancels in-flight poller-driven
+	// driver work instead of just the poller loop.
+	rootCtx    context.Context
+	rootCancel context.CancelFunc
+
 	sync.RWMutex
 
 	workersPool pool.Pool
@@ -40,6 +53,16 @@ type Plugin struct {
 	jobConstructors map[string]jobs.Constructor
 	consumers       map[string]jobs.Consumer
 
+	// middlewareByName holds every collected jobs.Middleware, keyed by its
+	// endure name; middlewareOrder records the order CollectMiddleware saw
+	// them in, since map iteration order is randomized per process and the
+	// default chain order must not be. middleware holds the final set
+	// ordered per config (or, absent config, middlewareOrder), built once
+	// in Serve.
+	middlewareByName map[string]jobs.Middleware
+	middlewareOrder  []string
+	middleware       []jobs.Middleware
+
 	events events.Handler
 
 	// priority queue implementation
@@ -51,6 +74,10 @@ type Plugin struct {
 	// initial set of the pipelines to consume
 	consume map[string]struct{}
 
+	// scheduler holds delayed/cron jobs for drivers without native delay
+	// support until they become due
+	scheduler *scheduler
+
 	stopCh chan struct{}
 }
 
@@ -69,11 +96,17 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 
 	p.server = server
 
+	p.rootCtx, p.rootCancel = context.WithCancel(context.Background())
+
 	p.events = events.NewEventsHandler()
 	p.events.AddListener(p.collectJobsEvents)
 
+	p.log = log
+	p.pollerLog = log.Named("jobs.poller")
+
 	p.jobConstructors = make(map[string]jobs.Constructor)
 	p.consumers = make(map[string]jobs.Consumer)
+	p.middlewareByName = make(map[string]jobs.Middleware)
 	p.consume = make(map[string]struct{})
 	p.stopCh = make(chan struct{}, 1)
 
@@ -90,15 +123,57 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 
 	// initialize priority queue
 	p.queue = priorityqueue.NewBinHeap(p.cfg.PipelineSize)
-	p.log = log
+
+	store, err := newBoltStore(p.cfg.SchedulerDB())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	// the scheduler drains due entries straight into the driver: entries
+	// scheduled by Push haven't been through the producer chain yet and
+	// need it (p.dispatch); entries scheduled by a retry have already
+	// been through it once and must not go through it again
+	// (p.pushRaw) - see retryJob in dlq.go.
+	p.scheduler, err = newScheduler(store, p.dispatch, p.pushRaw, log.Named("jobs.scheduler"))
+	if err != nil {
+		return errors.E(op, err)
+	}
 
 	return nil
 }
 
+// jobContext is the wire shape every driver's Item.Context() encodes -
+// see e.g. drivers/nats/item.go Context(). Decoding it recovers the
+// pipeline/headers a bare payload.Payload can't carry, both on the
+// consume side (the poller) and when rebuilding a delivered Item into a
+// retry/dead-letter Job (see dlq.go).
+type jobContext struct {
+	Job      string            `json:"job"`
+	ID       string            `json:"id"`
+	Headers  map[string][]byte `json:"headers,omitempty"`
+	Pipeline string            `json:"pipeline"`
+}
+
+// decodeJobContext best-effort decodes an Item's Context() bytes, returning
+// the zero value on malformed input - callers fall back to an empty
+// Job/Headers rather than failing the retry/dead-letter/dispatch outright.
+func decodeJobContext(raw []byte) jobContext {
+	var jctx jobContext
+	_ = json.Unmarshal(raw, &jctx)
+	return jctx
+}
+
 func (p *Plugin) Serve() chan error { //nolint:gocognit
 	errCh := make(chan error, 1)
 	const op = errors.Op("jobs_plugin_serve")
 
+	// arrange the collected middleware (gzip, otel-tracing, dedupe, ...)
+	// into the order configured by the user before any pipeline starts
+	p.orderMiddleware()
+
+	// start draining delayed/cron jobs as they become due
+	go p.scheduler.run()
+
 	// register initial pipelines
 	p.pipelines.Range(func(key, value interface{}) bool {
 		t := time.Now()
@@ -123,11 +198,16 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 				return false
 			}
 
+			// wrap the driver in a self-healing handle so a dropped
+			// transport doesn't take the whole pipeline down
+			driverLog := p.log.Named("jobs.driver." + dr)
+			handle := newLazyHandle(name, initializedDriver, c, configKey, pipe, p.events, p.queue, driverLog)
+
 			// add driver to the set of the consumers (name - pipeline name, value - associated driver)
-			p.consumers[name] = initializedDriver
+			p.consumers[name] = handle
 
 			// register pipeline for the initialized driver
-			err = initializedDriver.Register(pipe)
+			err = handle.Register(pipe)
 			if err != nil {
 				errCh <- errors.E(op, errors.Errorf("pipe register failed for the driver: %s with pipe name: %s", pipe.Driver(), pipe.Name()))
 				return false
@@ -135,13 +215,14 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 
 			// if pipeline initialized to be consumed, call Run on it
 			if _, ok := p.consume[name]; ok {
-				err = initializedDriver.Run(pipe)
+				err = handle.Run(p.rootCtx, pipe)
 				if err != nil {
 					errCh <- errors.E(op, err)
 					return false
 				}
 
 				p.events.Push(events.JobEvent{
+					Ctx:      p.rootCtx,
 					Event:    events.EventPipeRun,
 					Pipeline: pipe.Name(),
 					Driver:   pipe.Driver(),
@@ -155,6 +236,7 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 			return true
 		}
 		p.events.Push(events.JobEvent{
+			Ctx:      p.rootCtx,
 			Event:    events.EventDriverReady,
 			Pipeline: pipe.Name(),
 			Driver:   pipe.Driver(),
@@ -179,7 +261,7 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 				for {
 					select {
 					case <-p.stopCh:
-						p.log.Debug("------> job poller stopped <------")
+						p.pollerLog.Debug("------> job poller stopped <------")
 						return
 					default:
 						// get data JOB from the queue
@@ -189,36 +271,66 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 						if err != nil {
 							errNack := job.Nack()
 							if errNack != nil {
-								p.log.Error("negatively acknowledge failed", "error", errNack)
+								p.pollerLog.Error("negatively acknowledge failed", "error", errNack)
 							}
-							p.log.Error("job marshal context", "error", err)
+							p.pollerLog.Error("job marshal context", "error", err)
 							continue
 						}
 
-						exec := payload.Payload{
-							Context: ctx,
-							Body:    job.Body(),
+						// every driver's Item.Context() carries pipeline/headers
+						// alongside the body on the same wire shape; decode it so
+						// the consume-side middleware (dedupe, otel span
+						// continuation, ...) sees the same metadata the producer
+						// attached, not a bare payload
+						consumed := &structs.Job{Payload: string(job.Body())}
+						jctx := decodeJobContext(ctx)
+						consumed.Job = jctx.Job
+						consumed.Headers = jctx.Headers
+						consumed.Options = &structs.Options{ID: jctx.ID, Pipeline: jctx.Pipeline}
+
+						// run the consume-side middleware chain (decompression,
+						// schema validation, dedupe, tracing span injection, ...)
+						// ahead of dispatching the job body to the worker pool; a
+						// middleware that drops the job (dedupe) never reaches the
+						// final next, and the job is then acked without executing
+						forwarded := false
+						final := func(c context.Context, j *structs.Job) error {
+							forwarded = true
+							return noopNext(c, j)
 						}
-
-						// protect from the pool reset
-						p.RLock()
-						_, err = p.workersPool.Exec(exec)
-						if err != nil {
+						if err = p.chain(final)(structs.WithDirection(p.rootCtx, structs.ConsumeDirection), consumed); err != nil {
 							errNack := job.Nack()
 							if errNack != nil {
-								p.log.Error("negatively acknowledge failed", "error", errNack)
+								p.pollerLog.Error("negatively acknowledge failed", "error", errNack)
 							}
+							p.pollerLog.Error("job middleware", "error", err)
+							continue
+						}
 
-							p.RUnlock()
-							p.log.Error("job execute", "error", err)
+						if !forwarded {
+							if errAck := job.Ack(); errAck != nil {
+								p.pollerLog.Error("acknowledge failed", "error", errAck)
+							}
 							continue
 						}
+
+						exec := payload.Payload{
+							Context: ctx,
+							Body:    []byte(consumed.Payload),
+						}
+
+						// protect from the pool reset
+						p.RLock()
+						resp, execErr := p.workersPool.Exec(exec)
 						p.RUnlock()
 
-						errAck := job.Ack()
-						if errAck != nil {
-							p.log.Error("acknowledge failed", "error", errAck)
+						if execErr != nil {
+							p.pollerLog.Error("job execute", "error", execErr)
 						}
+
+						// decide retry / dead-letter / plain ack-nack based on
+						// the worker's response and the pipeline's DLQ policy
+						p.handleJobOutcome(p.rootCtx, job, resp, execErr)
 					}
 				}
 			}()
@@ -229,14 +341,24 @@ func (p *Plugin) Serve() chan error { //nolint:gocognit
 }
 
 func (p *Plugin) Stop() error {
+	p.scheduler.stop()
+
+	// give drivers a chance to drain gracefully on the still-live rootCtx
+	// before it's canceled; canceling first would hand Stop an
+	// already-done context and turn a graceful drain into an abrupt one
 	for k, v := range p.consumers {
-		err := v.Stop()
+		err := v.Stop(p.rootCtx)
 		if err != nil {
 			p.log.Error("stop job driver", "driver", k)
 			continue
 		}
 	}
 
+	// now cancel everything derived from p.rootCtx: any in-flight work
+	// that isn't covered by a driver's own Stop gets a chance to unwind
+	// instead of running until the process exits
+	p.rootCancel()
+
 	// this function can block forever, but we don't care, because we might have a chance to exit from the pollers,
 	// but if not, this is not a problem at all.
 	// The main target is to stop the drivers
@@ -256,6 +378,7 @@ func (p *Plugin) Stop() error {
 func (p *Plugin) Collects() []interface{} {
 	return []interface{}{
 		p.CollectMQBrokers,
+		p.CollectMiddleware,
 	}
 }
 
@@ -289,7 +412,10 @@ func (p *Plugin) Reset() error {
 	return nil
 }
 
-func (p *Plugin) Push(j *structs.Job) error {
+// Push enqueues a job onto its pipeline's driver. ctx carries the caller's
+// deadline/cancellation and any tracing span into the driver call and, via
+// the middleware chain, into job.Context() for the worker.
+func (p *Plugin) Push(ctx context.Context, j *structs.Job) error {
 	const op = errors.Op("jobs_plugin_push")
 
 	// get the pipeline for the job
@@ -312,38 +438,91 @@ func (p *Plugin) Push(j *structs.Job) error {
 		j.Options.Priority = ppl.Priority()
 	}
 
-	err := d.Push(j)
-	if err != nil {
+	// delayed/cron jobs go through the scheduler unless the driver can
+	// honor the delay natively (SQS DelaySeconds, AMQP delayed exchange,
+	// Beanstalk pri/delay)
+	if j.Options.Cron != "" || !j.Options.DelayUntil.IsZero() {
+		if nd, ok := d.(NativeDelayer); !ok || !nd.SupportsDelay() {
+			at, err := readyTime(j)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			// raw=false: this job hasn't been through the producer chain
+			// yet, so the scheduler must dispatch it through p.dispatch
+			// (chain + driver), not push it to the driver directly.
+			if _, err = p.scheduler.schedule(j, at, j.Options.Cron, false); err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		}
+	}
+
+	if err := p.dispatch(ctx, j); err != nil {
 		return errors.E(op, err)
 	}
 
 	return nil
 }
 
-func (p *Plugin) PushBatch(j []*structs.Job) error {
-	const op = errors.Op("jobs_plugin_push")
+// dispatch runs the producer middleware chain (compression, tracing span
+// injection, ...) and hands the result to the pipeline's driver. It
+// assumes the pipeline/driver lookup already happened (Push) or the
+// caller already knows them (the scheduler); it does not re-check
+// Options.Cron/DelayUntil, so it must never be reachable from the
+// scheduler's drain loop in a way that loops back into scheduling.
+func (p *Plugin) dispatch(ctx context.Context, j *structs.Job) error {
+	const op = errors.Op("jobs_plugin_dispatch")
 
-	for i := 0; i < len(j); i++ {
-		// get the pipeline for the job
-		pipe, ok := p.pipelines.Load(j[i].Options.Pipeline)
-		if !ok {
-			return errors.E(op, errors.Errorf("no such pipeline, requested: %s", j[i].Options.Pipeline))
-		}
+	pipe, ok := p.pipelines.Load(j.Options.Pipeline)
+	if !ok {
+		return errors.E(op, errors.Errorf("no such pipeline, requested: %s", j.Options.Pipeline))
+	}
 
-		ppl := pipe.(*pipeline.Pipeline)
+	ppl := pipe.(*pipeline.Pipeline)
 
-		d, ok := p.consumers[ppl.Name()]
-		if !ok {
-			return errors.E(op, errors.Errorf("consumer not registered for the requested driver: %s", ppl.Driver()))
-		}
+	d, ok := p.consumers[ppl.Name()]
+	if !ok {
+		return errors.E(op, errors.Errorf("consumer not registered for the requested driver: %s", ppl.Driver()))
+	}
 
-		// if job has no priority, inherit it from the pipeline
-		if j[i].Options.Priority == 0 {
-			j[i].Options.Priority = ppl.Priority()
-		}
+	return p.chain(func(_ context.Context, j *structs.Job) error {
+		return d.Push(ctx, j)
+	})(structs.WithDirection(ctx, structs.PushDirection), j)
+}
 
-		err := d.Push(j[i])
-		if err != nil {
+// pushRaw hands j straight to its pipeline's driver, skipping the producer
+// middleware chain entirely. Used for jobs rebuilt from an already-delivered
+// message (retry/dead-letter): their Payload has already been through
+// compression/encryption once, and running that chain again would apply it
+// a second time (gzip would compress an already-compressed retry payload
+// again).
+func (p *Plugin) pushRaw(ctx context.Context, j *structs.Job) error {
+	const op = errors.Op("jobs_plugin_push_raw")
+
+	pipe, ok := p.pipelines.Load(j.Options.Pipeline)
+	if !ok {
+		return errors.E(op, errors.Errorf("no such pipeline, requested: %s", j.Options.Pipeline))
+	}
+
+	ppl := pipe.(*pipeline.Pipeline)
+
+	d, ok := p.consumers[ppl.Name()]
+	if !ok {
+		return errors.E(op, errors.Errorf("consumer not registered for the requested driver: %s", ppl.Driver()))
+	}
+
+	return d.Push(ctx, j)
+}
+
+// PushBatch pushes every job with the same ctx, stopping at the first
+// failure.
+func (p *Plugin) PushBatch(ctx context.Context, j []*structs.Job) error {
+	const op = errors.Op("jobs_plugin_push")
+
+	for i := 0; i < len(j); i++ {
+		if err := p.Push(ctx, j[i]); err != nil {
 			return errors.E(op, err)
 		}
 	}
@@ -351,7 +530,7 @@ func (p *Plugin) PushBatch(j []*structs.Job) error {
 	return nil
 }
 
-func (p *Plugin) Pause(pipelines []string) {
+func (p *Plugin) Pause(ctx context.Context, pipelines []string) {
 	for i := 0; i < len(pipelines); i++ {
 		pipe, ok := p.pipelines.Load(pipelines[i])
 		if !ok {
@@ -367,11 +546,11 @@ func (p *Plugin) Pause(pipelines []string) {
 		}
 
 		// redirect call to the underlying driver
-		d.Pause(ppl.Name())
+		d.Pause(ctx, ppl.Name())
 	}
 }
 
-func (p *Plugin) Resume(pipelines []string) {
+func (p *Plugin) Resume(ctx context.Context, pipelines []string) {
 	for i := 0; i < len(pipelines); i++ {
 		pipe, ok := p.pipelines.Load(pipelines[i])
 		if !ok {
@@ -387,12 +566,12 @@ func (p *Plugin) Resume(pipelines []string) {
 		}
 
 		// redirect call to the underlying driver
-		d.Resume(ppl.Name())
+		d.Resume(ctx, ppl.Name())
 	}
 }
 
 // Declare a pipeline.
-func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
+func (p *Plugin) Declare(ctx context.Context, pipeline *pipeline.Pipeline) error {
 	const op = errors.Op("jobs_plugin_declare")
 	// driver for the pipeline (ie amqp, ephemeral, etc)
 	dr := pipeline.Driver()
@@ -409,18 +588,24 @@ func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
 			return errors.E(op, err)
 		}
 
+		driverLog := p.log.Named("jobs.driver." + dr)
+
+		// wrap the driver in a self-healing handle so a dropped
+		// transport doesn't take the whole pipeline down
+		handle := newLazyHandle(pipeline.Name(), initializedDriver, c, "", pipeline, p.events, p.queue, driverLog)
+
 		// add driver to the set of the consumers (name - pipeline name, value - associated driver)
-		p.consumers[pipeline.Name()] = initializedDriver
+		p.consumers[pipeline.Name()] = handle
 
 		// register pipeline for the initialized driver
-		err = initializedDriver.Register(pipeline)
+		err = handle.Register(pipeline)
 		if err != nil {
 			return errors.E(op, errors.Errorf("pipe register failed for the driver: %s with pipe name: %s", pipeline.Driver(), pipeline.Name()))
 		}
 
 		// if pipeline initialized to be consumed, call Run on it
 		if _, ok := p.consume[pipeline.Name()]; ok {
-			err = initializedDriver.Run(pipeline)
+			err = handle.Run(ctx, pipeline)
 			if err != nil {
 				return errors.E(op, err)
 			}
@@ -433,7 +618,7 @@ func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
 }
 
 // Destroy pipeline and release all associated resources.
-func (p *Plugin) Destroy(pp string) error {
+func (p *Plugin) Destroy(ctx context.Context, pp string) error {
 	const op = errors.Op("jobs_plugin_destroy")
 	pipe, ok := p.pipelines.Load(pp)
 	if !ok {
@@ -452,7 +637,7 @@ func (p *Plugin) Destroy(pp string) error {
 	delete(p.consumers, ppl.Name())
 	p.pipelines.Delete(pp)
 
-	return d.Stop()
+	return d.Stop(ctx)
 }
 
 func (p *Plugin) List() []string {
@@ -499,6 +684,14 @@ func (p *Plugin) collectJobsEvents(event interface{}) {
 			p.log.Info("driver ready", "pipeline", jev.Pipeline, "start", jev.Start.UTC(), "elapsed", jev.Elapsed)
 		case events.EventInitialized:
 			p.log.Info("driver initialized", "driver", jev.Driver, "start", jev.Start.UTC())
+		case events.EventDriverDegraded:
+			p.log.Warn("driver degraded, entering recovery", "pipeline", jev.Pipeline, "error", jev.Error, "start", jev.Start.UTC())
+		case events.EventDriverRecovered:
+			p.log.Info("driver recovered", "pipeline", jev.Pipeline, "start", jev.Start.UTC())
+		case events.EventJobRetried:
+			p.log.Warn("job retried", "pipeline", jev.Pipeline, "start", jev.Start.UTC())
+		case events.EventJobDeadLettered:
+			p.log.Warn("job dead-lettered", "pipeline", jev.Pipeline, "start", jev.Start.UTC())
 		}
 	}
 }
@@ -0,0 +1,22 @@
+package jobs
+
+import "github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+
+// Schedule registers a delayed or cron job via RPC and returns the
+// scheduler-assigned ID, which Unschedule accepts to cancel it.
+func (r *rpc) Schedule(j *structs.Job, id *string) error {
+	scheduled, err := r.p.Schedule(j)
+	if err != nil {
+		return err
+	}
+
+	*id = scheduled
+
+	return nil
+}
+
+// Unschedule cancels a pending delayed or cron job by the ID Schedule
+// returned.
+func (r *rpc) Unschedule(id string, _ *bool) error {
+	return r.p.Unschedule(id)
+}
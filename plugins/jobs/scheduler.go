@@ -0,0 +1,323 @@
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+	"github.com/spiral/roadrunner/v2/plugins/logger"
+)
+
+// tickInterval is how often the scheduler checks the heap for due entries.
+// Delayed/cron jobs don't need second-precision firing, so this is cheap
+// enough to run continuously.
+const tickInterval = time.Second
+
+// scheduledEntry is one pending delayed or cron job.
+type scheduledEntry struct {
+	ID    string       `json:"id"`
+	Job   *structs.Job `json:"job"`
+	Ready time.Time    `json:"ready"`
+	Cron  string       `json:"cron,omitempty"`
+
+	// Raw marks an entry whose Job has already been through the push-side
+	// middleware chain once (a retry re-delivery) and must
+	// be drained straight to the driver, not back through the chain.
+	Raw bool `json:"raw,omitempty"`
+}
+
+// schedHeap is a container/heap.Interface over scheduledEntry, ordered by
+// Ready time so ExtractMin-style access is O(log n).
+type schedHeap []*scheduledEntry
+
+func (h schedHeap) Len() int            { return len(h) }
+func (h schedHeap) Less(i, j int) bool  { return h[i].Ready.Before(h[j].Ready) }
+func (h schedHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *schedHeap) Push(x interface{}) { *h = append(*h, x.(*scheduledEntry)) }
+func (h *schedHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// scheduler holds delayed/cron jobs that aren't due yet and, on a ticker,
+// pushes due entries straight into the driver (push or pushRaw, depending
+// on entry.Raw) - used for drivers without native delay support (ephemeral,
+// in-memory). Drivers that do support native delay (SQS DelaySeconds, AMQP
+// delayed exchange, Beanstalk pri/delay) never see their jobs enter this
+// heap; see the NativeDelayer check in Plugin.Push, which runs before the
+// scheduler is involved.
+type scheduler struct {
+	mu sync.Mutex
+	h  schedHeap
+
+	byID  map[string]*scheduledEntry
+	store SchedulerStore
+
+	// push dispatches a job that has not yet been through the push-side
+	// middleware chain (ordinary delayed/cron jobs scheduled via Push or
+	// Schedule). pushRaw dispatches a job that has already been through it
+	// once - straight to the driver - used for retry entries (see
+	// Plugin.retryJob).
+	push    func(ctx context.Context, j *structs.Job) error
+	pushRaw func(ctx context.Context, j *structs.Job) error
+
+	log    logger.Logger
+	ticker *time.Ticker
+	stopCh chan struct{}
+}
+
+func newScheduler(store SchedulerStore, push func(ctx context.Context, j *structs.Job) error, pushRaw func(ctx context.Context, j *structs.Job) error, log logger.Logger) (*scheduler, error) {
+	const op = errors.Op("scheduler_new")
+
+	s := &scheduler{
+		byID:    make(map[string]*scheduledEntry),
+		store:   store,
+		push:    push,
+		pushRaw: pushRaw,
+		log:     log,
+		stopCh:  make(chan struct{}, 1),
+	}
+
+	heap.Init(&s.h)
+
+	existing, err := store.Load()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	for _, e := range existing {
+		heap.Push(&s.h, e)
+		s.byID[e.ID] = e
+	}
+
+	return s, nil
+}
+
+// run starts the draining ticker; it returns once stop() is called.
+func (s *scheduler) run() {
+	s.ticker = time.NewTicker(tickInterval)
+
+	for {
+		select {
+		case <-s.stopCh:
+			s.ticker.Stop()
+			return
+		case <-s.ticker.C:
+			s.drainDue()
+		}
+	}
+}
+
+func (s *scheduler) stop() {
+	s.stopCh <- struct{}{}
+}
+
+// schedule inserts a job to fire at ready (delay) or, for cron entries, at
+// the next occurrence after now. raw marks an entry as already having gone
+// through the push-side chain once (see scheduler.pushRaw).
+func (s *scheduler) schedule(j *structs.Job, ready time.Time, cronExpr string, raw bool) (string, error) {
+	const op = errors.Op("scheduler_schedule")
+
+	id := j.Options.ID
+	if id == "" {
+		id = j.Options.Pipeline + "-" + ready.Format(time.RFC3339Nano)
+	}
+
+	entry := &scheduledEntry{ID: id, Job: j, Ready: ready, Cron: cronExpr, Raw: raw}
+
+	if err := s.store.Save(entry); err != nil {
+		return "", errors.E(op, err)
+	}
+
+	s.mu.Lock()
+	heap.Push(&s.h, entry)
+	s.byID[id] = entry
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+// unschedule removes a pending entry by ID; firing or already-fired
+// entries are not affected.
+func (s *scheduler) unschedule(id string) error {
+	s.mu.Lock()
+	entry, ok := s.byID[id]
+	if ok {
+		delete(s.byID, id)
+		for i := range s.h {
+			if s.h[i] == entry {
+				heap.Remove(&s.h, i)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return errors.E(errors.Op("scheduler_unschedule"), errors.Errorf("no such scheduled job: %s", id))
+	}
+
+	return s.store.Delete(id)
+}
+
+// pending reports the number of entries waiting in the heap, surfaced to
+// operators through the structured logger.
+func (s *scheduler) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.h)
+}
+
+// popDue pops and returns every entry in h whose Ready time is not after
+// now, leaving the rest of the heap intact. Split out of drainDue so the
+// due-job selection can be unit tested against a bare schedHeap, without
+// the scheduler's store/push dependencies.
+func popDue(h *schedHeap, now time.Time) []*scheduledEntry {
+	var due []*scheduledEntry
+
+	for h.Len() > 0 && !(*h)[0].Ready.After(now) {
+		due = append(due, heap.Pop(h).(*scheduledEntry))
+	}
+
+	return due
+}
+
+// drainDue pops every entry whose Ready time has passed, pushes it, and
+// re-schedules cron entries for their next occurrence.
+func (s *scheduler) drainDue() {
+	now := time.Now()
+
+	s.mu.Lock()
+	due := popDue(&s.h, now)
+	for _, entry := range due {
+		delete(s.byID, entry.ID)
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	s.log.Debug("scheduler draining due jobs", "count", len(due), "pending", s.pending())
+
+	for _, entry := range due {
+		push := s.push
+		if entry.Raw {
+			push = s.pushRaw
+		}
+
+		// push a copy: the push-side chain rewrites a job's Payload and
+		// Headers in place, and a cron entry's Job is rescheduled below
+		job := *entry.Job
+		job.Headers = make(map[string][]byte, len(entry.Job.Headers))
+		for k, v := range entry.Job.Headers {
+			job.Headers[k] = v
+		}
+
+		if err := push(context.Background(), &job); err != nil {
+			s.log.Error("scheduled job push failed", "id", entry.ID, "error", err)
+		}
+
+		if err := s.store.Delete(entry.ID); err != nil {
+			s.log.Error("scheduler store delete failed", "id", entry.ID, "error", err)
+		}
+
+		if entry.Cron == "" {
+			continue
+		}
+
+		next, err := nextCronFire(entry.Cron, now)
+		if err != nil {
+			s.log.Error("cron expression invalid, dropping", "id", entry.ID, "cron", entry.Cron, "error", err)
+			continue
+		}
+
+		// keep the entry's ID across fires, so Unschedule still accepts
+		// the ID Schedule returned
+		if entry.Job.Options.ID == "" {
+			entry.Job.Options.ID = entry.ID
+		}
+
+		if _, err = s.schedule(entry.Job, next, entry.Cron, entry.Raw); err != nil {
+			s.log.Error("cron reschedule failed", "id", entry.ID, "error", err)
+		}
+	}
+}
+
+// nextCronFire parses a standard 5-field cron expression and returns its
+// next occurrence strictly after after.
+func nextCronFire(expr string, after time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return sched.Next(after), nil
+}
+
+// NativeDelayer is implemented by drivers whose transport understands
+// delay/scheduling natively (SQS DelaySeconds, AMQP delayed exchange,
+// Beanstalk pri/delay). Plugin.Push checks for it before falling back to
+// the plugin-level scheduler so those drivers keep handling delay
+// themselves.
+type NativeDelayer interface {
+	SupportsDelay() bool
+}
+
+// readyTime resolves the time a delayed/cron job should first fire.
+func readyTime(j *structs.Job) (time.Time, error) {
+	if j.Options.Cron != "" {
+		return nextCronFire(j.Options.Cron, time.Now())
+	}
+
+	return j.Options.DelayUntil, nil
+}
+
+// Schedule registers a delayed or cron job with the scheduler. Jobs with
+// Options.DelayUntil set fire once at that time; jobs with Options.Cron
+// set are expanded to their next occurrence on every successful dispatch.
+func (p *Plugin) Schedule(j *structs.Job) (string, error) {
+	const op = errors.Op("jobs_plugin_schedule")
+
+	var ready time.Time
+
+	switch {
+	case j.Options.Cron != "":
+		next, err := nextCronFire(j.Options.Cron, time.Now())
+		if err != nil {
+			return "", errors.E(op, err)
+		}
+		ready = next
+	case !j.Options.DelayUntil.IsZero():
+		ready = j.Options.DelayUntil
+	default:
+		return "", errors.E(op, errors.Errorf("job has neither DelayUntil nor Cron set"))
+	}
+
+	id, err := p.scheduler.schedule(j, ready, j.Options.Cron, false)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	return id, nil
+}
+
+// Unschedule cancels a previously scheduled job by the ID returned from
+// Schedule.
+func (p *Plugin) Unschedule(id string) error {
+	const op = errors.Op("jobs_plugin_unschedule")
+
+	if err := p.scheduler.unschedule(id); err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
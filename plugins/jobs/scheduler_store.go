@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spiral/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// schedulerBucket is the bbolt bucket holding persisted scheduled entries,
+// so a restart doesn't lose delayed/cron jobs that haven't fired yet.
+var schedulerBucket = []byte("jobs_scheduler")
+
+// SchedulerStore persists scheduled entries so the scheduler's min-heap can
+// be rebuilt across restarts. The default implementation is bbolt-backed;
+// a plugin can substitute any KV by satisfying the same interface.
+type SchedulerStore interface {
+	Save(entry *scheduledEntry) error
+	Delete(id string) error
+	Load() ([]*scheduledEntry, error)
+}
+
+// boltStore is the default, file-backed SchedulerStore.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// newBoltStore opens (creating if necessary) the bbolt file at path and
+// ensures the scheduler bucket exists.
+func newBoltStore(path string) (*boltStore, error) {
+	const op = errors.Op("scheduler_store_open")
+
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(schedulerBucket)
+		return err
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Save(entry *scheduledEntry) error {
+	const op = errors.Op("scheduler_store_save")
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulerBucket).Put([]byte(entry.ID), data)
+	})
+}
+
+func (s *boltStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulerBucket).Delete([]byte(id))
+	})
+}
+
+func (s *boltStore) Load() ([]*scheduledEntry, error) {
+	const op = errors.Op("scheduler_store_load")
+
+	var entries []*scheduledEntry
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulerBucket).ForEach(func(_, v []byte) error {
+			entry := &scheduledEntry{}
+			if err := json.Unmarshal(v, entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return entries, nil
+}
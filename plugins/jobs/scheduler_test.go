@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner/v2/plugins/jobs/structs"
+)
+
+func entryAt(id string, ready time.Time) *scheduledEntry {
+	return &scheduledEntry{ID: id, Job: &structs.Job{}, Ready: ready}
+}
+
+func TestPopDueDrainsOnlyEntriesAtOrBeforeNow(t *testing.T) {
+	now := time.Now()
+
+	h := schedHeap{}
+	heap.Init(&h)
+
+	heap.Push(&h, entryAt("past", now.Add(-time.Minute)))
+	heap.Push(&h, entryAt("exactly-now", now))
+	heap.Push(&h, entryAt("future", now.Add(time.Minute)))
+
+	due := popDue(&h, now)
+
+	if len(due) != 2 {
+		t.Fatalf("popDue() returned %d entries, want 2", len(due))
+	}
+
+	ids := map[string]bool{}
+	for _, e := range due {
+		ids[e.ID] = true
+	}
+
+	if !ids["past"] || !ids["exactly-now"] {
+		t.Fatalf("popDue() = %v, want past and exactly-now", ids)
+	}
+
+	if h.Len() != 1 || h[0].ID != "future" {
+		t.Fatalf("popDue() left %d entries in the heap, want only \"future\"", h.Len())
+	}
+}
+
+func TestPopDueEmptyHeap(t *testing.T) {
+	h := schedHeap{}
+	heap.Init(&h)
+
+	if due := popDue(&h, time.Now()); len(due) != 0 {
+		t.Fatalf("popDue() on an empty heap returned %d entries, want 0", len(due))
+	}
+}
+
+func TestPopDueOrdersByReadyTime(t *testing.T) {
+	now := time.Now()
+
+	h := schedHeap{}
+	heap.Init(&h)
+
+	heap.Push(&h, entryAt("third", now.Add(-time.Second)))
+	heap.Push(&h, entryAt("first", now.Add(-time.Hour)))
+	heap.Push(&h, entryAt("second", now.Add(-time.Minute)))
+
+	due := popDue(&h, now)
+
+	want := []string{"first", "second", "third"}
+	if len(due) != len(want) {
+		t.Fatalf("popDue() returned %d entries, want %d", len(due), len(want))
+	}
+
+	for i, id := range want {
+		if due[i].ID != id {
+			t.Fatalf("popDue()[%d].ID = %q, want %q", i, due[i].ID, id)
+		}
+	}
+}
+
+func TestNextCronFire(t *testing.T) {
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	next, err := nextCronFire("0 0 * * *", after)
+	if err != nil {
+		t.Fatalf("nextCronFire() error = %v", err)
+	}
+
+	want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Fatalf("nextCronFire() = %v, want %v", next, want)
+	}
+
+	if _, err := nextCronFire("not a cron expr", after); err == nil {
+		t.Fatal("nextCronFire() with an invalid expression expected an error, got nil")
+	}
+}
+
+func TestReadyTime(t *testing.T) {
+	delayUntil := time.Now().Add(time.Hour)
+
+	j := &structs.Job{Options: &structs.Options{DelayUntil: delayUntil}}
+	ready, err := readyTime(j)
+	if err != nil {
+		t.Fatalf("readyTime() error = %v", err)
+	}
+	if !ready.Equal(delayUntil) {
+		t.Fatalf("readyTime() = %v, want %v", ready, delayUntil)
+	}
+
+	cronJob := &structs.Job{Options: &structs.Options{Cron: "0 0 * * *"}}
+	if _, err := readyTime(cronJob); err != nil {
+		t.Fatalf("readyTime() with Cron set error = %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package structs
+
+import "context"
+
+// Direction distinguishes which leg of the middleware chain a Process
+// call is running on. Middleware whose behavior must differ between the
+// two legs (dedupe, which would otherwise see its own produced job twice
+// - once on push, once on consume, for any pipeline the same process
+// both produces to and consumes from) reads this instead of assuming
+// both legs look the same.
+type Direction int
+
+const (
+	// PushDirection is the producer leg: Plugin.Push/PushBatch, before
+	// the driver accepts the job.
+	PushDirection Direction = iota
+
+	// ConsumeDirection is the consumer leg: the poller, before the job
+	// reaches a worker.
+	ConsumeDirection
+)
+
+type directionKey struct{}
+
+// WithDirection attaches d to ctx for the middleware chain to read back
+// via DirectionOf.
+func WithDirection(ctx context.Context, d Direction) context.Context {
+	return context.WithValue(ctx, directionKey{}, d)
+}
+
+// DirectionOf reports the Direction attached to ctx, defaulting to
+// PushDirection when none was set.
+func DirectionOf(ctx context.Context) Direction {
+	d, _ := ctx.Value(directionKey{}).(Direction)
+	return d
+}
@@ -0,0 +1,64 @@
+// Package structs defines the Job/Options types the jobs plugin, its
+// drivers, and its middleware all operate on - the common currency
+// pushed through Plugin.Push, carried over a driver's wire format, and
+// mutated in place by the middleware chain.
+package structs
+
+import "time"
+
+// Options carries per-job delivery/scheduling metadata, independent of
+// the driver that ends up handling the job.
+type Options struct {
+	// ID uniquely identifies the job; drivers that don't assign their own
+	// message ID use this to correlate Ack/Nack back to the request, and
+	// the scheduler uses it as the default entry key.
+	ID string `json:"id,omitempty"`
+
+	// Pipeline is the name of the pipeline the job is pushed to/consumed
+	// from.
+	Pipeline string `json:"pipeline"`
+
+	// Priority orders jobs within a pipeline's queue; zero inherits the
+	// pipeline's configured default.
+	Priority int64 `json:"priority,omitempty"`
+
+	// Delay is a driver-native delay in seconds (SQS DelaySeconds, AMQP
+	// delayed exchange, Beanstalk pri/delay). DelayUntil is used instead
+	// for drivers without native support - see the plugin's scheduler.
+	Delay int64 `json:"delay,omitempty"`
+
+	// DelayUntil is the absolute time a delayed job becomes ready to run.
+	// Set either this or Cron, never both.
+	DelayUntil time.Time `json:"delay_until,omitempty"`
+
+	// Cron is a standard 5-field cron expression. A Cron job is expanded
+	// to its next occurrence on every successful dispatch instead of
+	// being consumed once.
+	Cron string `json:"cron,omitempty"`
+
+	// Attempts counts how many times this job (or its predecessor before
+	// a retry) has been dispatched to a worker. Incremented by
+	// Plugin.handleJobOutcome on every retry/dead-letter and propagated
+	// through the driver's wire format so RetryableItem can report it
+	// back on redelivery.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// Job is the payload pushed onto a pipeline and the value every driver,
+// middleware, and the scheduler operate on.
+type Job struct {
+	// Job is the PHP-side job handler name.
+	Job string `json:"job"`
+
+	// Payload is the job body; middleware (gzip, encryption, ...) operate
+	// on this field in place.
+	Payload string `json:"payload"`
+
+	// Headers carry out-of-band metadata (idempotency keys, trace
+	// context, ...) alongside the payload.
+	Headers map[string][]byte `json:"headers,omitempty"`
+
+	// Options configures delivery/scheduling/retry behavior; must not be
+	// nil on a job headed into Plugin.Push.
+	Options *Options `json:"options,omitempty"`
+}